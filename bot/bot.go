@@ -0,0 +1,211 @@
+// Package bot реализует команды Telegram-бота NtpSpy поверх
+// транспорта notifier.Sink (Bot API или MTProto): принимает команды из
+// настроенного чата и рассылает уведомления о синхронизациях NTP-клиентов.
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hermanguilliman/NtpSpy/notifier"
+	"go.uber.org/zap"
+)
+
+// GeoResult - геоданные по IP, используемые командой /whois.
+type GeoResult struct {
+	Country string
+	City    string
+	ASN     string
+	ISP     string
+}
+
+// GeoLookupFunc - функция геолокации, которую предоставляет вызывающий код.
+type GeoLookupFunc func(ctx context.Context, ip string) (GeoResult, error)
+
+// Bot - дерево команд поверх транспорта notifier.Sink.
+type Bot struct {
+	sink      notifier.Sink
+	chatID    string
+	allowlist map[string]struct{}
+	logger    *zap.Logger
+
+	registry  *Registry
+	rec       Recorder
+	geoLookup GeoLookupFunc
+
+	notifyChan chan string
+
+	dedupSetter DedupSetter
+
+	mu         sync.Mutex
+	mutedUntil time.Time
+}
+
+// DedupSetter - хот-релоадный порог дедупликации уведомлений,
+// которым управляет команда /setdedup.
+type DedupSetter interface {
+	SetTTL(d time.Duration)
+}
+
+// Option - опция конфигурации Bot.
+type Option func(*Bot)
+
+// WithGeoLookup - задаёт функцию геолокации для команды /whois.
+func WithGeoLookup(fn GeoLookupFunc) Option {
+	return func(b *Bot) { b.geoLookup = fn }
+}
+
+// WithDedupSetter - подключает слой дедупликации уведомлений, чтобы
+// команда /setdedup могла менять его порог на лету.
+func WithDedupSetter(s DedupSetter) Option {
+	return func(b *Bot) { b.dedupSetter = s }
+}
+
+// WithRecorder - задаёт источник данных для /stats и /recent.
+func WithRecorder(rec Recorder) Option {
+	return func(b *Bot) { b.rec = rec }
+}
+
+// WithAllowlist - ограничивает приём команд дополнительными чатами
+// сверх основного chatID, переданного в NewBot (например, личные
+// чаты админов при transport: mtproto). Пустой allowlist не меняет
+// поведение - команды по-прежнему принимаются только из chatID.
+func WithAllowlist(allowlist []string) Option {
+	return func(b *Bot) {
+		for _, id := range allowlist {
+			b.allowlist[id] = struct{}{}
+		}
+	}
+}
+
+// NewBot - создаёт дерево команд поверх sink. chatID - чат, из
+// которого принимаются команды; сообщения из остальных чатов
+// игнорируются (актуально для transport: mtproto, где клиент - это
+// полноценный пользовательский аккаунт, а не изолированный бот). По
+// умолчанию события хранятся в памяти процесса (см. WithRecorder для замены).
+func NewBot(sink notifier.Sink, chatID string, logger *zap.Logger, opts ...Option) *Bot {
+	b := &Bot{
+		sink:       sink,
+		chatID:     chatID,
+		allowlist:  make(map[string]struct{}),
+		logger:     logger,
+		registry:   NewRegistry(),
+		rec:        NewMemRecorder(),
+		notifyChan: make(chan string, 100),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	registerDefaultCommands(b.registry)
+
+	return b
+}
+
+// SetDedupSetter - подключает слой дедупликации уведомлений уже после
+// создания бота, когда он сам принимает бота как sink.
+func (b *Bot) SetDedupSetter(s DedupSetter) {
+	b.dedupSetter = s
+}
+
+// Record - сохраняет событие синхронизации для последующих /stats и /recent.
+func (b *Bot) Record(ev Event) {
+	b.rec.Record(ev)
+}
+
+// Notify - ставит уведомление в очередь на отправку в чат. Если бот
+// находится в режиме /mute, уведомление тихо отбрасывается - событие
+// при этом уже залогировано вызывающим кодом.
+func (b *Bot) Notify(msg string) {
+	select {
+	case b.notifyChan <- msg:
+	default:
+		b.logger.Warn("Очередь уведомлений бота переполнена, сообщение отброшено")
+	}
+}
+
+// Listen - подписывается на входящие сообщения sink и рассылает
+// уведомления из очереди. Блокируется до отмены ctx.
+func (b *Bot) Listen(ctx context.Context) {
+	b.sink.Subscribe(func(in notifier.Incoming) {
+		if !b.isAuthorized(in.ChatID) {
+			return
+		}
+		b.dispatch(ctx, in.Text)
+	})
+
+	go func() {
+		if err := b.sink.Run(ctx); err != nil {
+			b.logger.Warn("Транспорт Telegram завершился с ошибкой", zap.Error(err))
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.Info("Остановка обработки сообщений Telegram")
+			return
+		case msg := <-b.notifyChan:
+			if b.isMuted() {
+				continue
+			}
+			if err := b.sink.Send(ctx, msg); err != nil {
+				b.logger.Warn("Ошибка отправки сообщения в Telegram", zap.Error(err))
+			}
+		}
+	}
+}
+
+// isAuthorized - проверяет, разрешено ли chatID присылать команды:
+// основной chatID всегда разрешён, дополнительные - через WithAllowlist.
+func (b *Bot) isAuthorized(chatID string) bool {
+	if chatID == b.chatID {
+		return true
+	}
+	_, ok := b.allowlist[chatID]
+	return ok
+}
+
+func (b *Bot) isMuted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Now().Before(b.mutedUntil)
+}
+
+func (b *Bot) mute(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mutedUntil = time.Now().Add(d)
+}
+
+func (b *Bot) unmute() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mutedUntil = time.Time{}
+}
+
+func (b *Bot) dispatch(ctx context.Context, text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+
+	name := strings.TrimPrefix(fields[0], "/")
+	cmd, ok := b.registry.Lookup(name)
+	if !ok {
+		return
+	}
+
+	reply, err := cmd.Handler(ctx, b, fields[1:])
+	if err != nil {
+		reply = fmt.Sprintf("Ошибка: %s", err.Error())
+	}
+
+	if err := b.sink.Send(ctx, reply); err != nil {
+		b.logger.Warn("Ошибка отправки ответа на команду", zap.String("command", name), zap.Error(err))
+	}
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// registerDefaultCommands - регистрирует встроенные команды бота.
+func registerDefaultCommands(r *Registry) {
+	r.Register(Command{Name: "stats", Usage: "статистика синхронизаций за сегодня", Handler: handleStats})
+	r.Register(Command{Name: "recent", Usage: "recent N - последние N клиентов с геоданными", Handler: handleRecent})
+	r.Register(Command{Name: "mute", Usage: "mute 1h - приостановить уведомления на указанное время", Handler: handleMute})
+	r.Register(Command{Name: "unmute", Usage: "снять паузу с уведомлений", Handler: handleUnmute})
+	r.Register(Command{Name: "whois", Usage: "whois <ip> - геолокация по IP прямо сейчас", Handler: handleWhois})
+	r.Register(Command{Name: "setdedup", Usage: "setdedup 1h - порог повторного уведомления об одном IP", Handler: handleSetDedup})
+	r.Register(Command{Name: "help", Usage: "список доступных команд", Handler: handleHelp})
+}
+
+func handleHelp(_ context.Context, b *Bot, _ []string) (string, error) {
+	return b.registry.Help(), nil
+}
+
+func handleStats(_ context.Context, b *Bot, _ []string) (string, error) {
+	since := time.Now().Truncate(24 * time.Hour)
+	stats := b.rec.Stats(since)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Синхронизаций сегодня: %d\nУникальных IP: %d\n", stats.Total, stats.UniqueIPs)
+
+	if len(stats.TopCountries) > 0 {
+		out.WriteString("\nТоп стран:\n")
+		for _, c := range stats.TopCountries {
+			fmt.Fprintf(&out, "  %s: %d\n", c.Value, c.Count)
+		}
+	}
+
+	if len(stats.TopASNs) > 0 {
+		out.WriteString("\nТоп ASN:\n")
+		for _, a := range stats.TopASNs {
+			fmt.Fprintf(&out, "  %s: %d\n", a.Value, a.Count)
+		}
+	}
+
+	return strings.TrimRight(out.String(), "\n"), nil
+}
+
+func handleRecent(_ context.Context, b *Bot, args []string) (string, error) {
+	n := 10
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed <= 0 {
+			return "", fmt.Errorf("некорректное число: %s", args[0])
+		}
+		n = parsed
+	}
+
+	events := b.rec.Recent(n)
+	if len(events) == 0 {
+		return "Пока нет ни одного события", nil
+	}
+
+	var out strings.Builder
+	for _, ev := range events {
+		fmt.Fprintf(&out, "%s  %s  %s %s\n", ev.Time.Format(time.RFC3339), ev.IP, ev.Country, ev.ASN)
+	}
+
+	return strings.TrimRight(out.String(), "\n"), nil
+}
+
+func handleMute(_ context.Context, b *Bot, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("укажите длительность, например /mute 1h")
+	}
+
+	d, err := time.ParseDuration(args[0])
+	if err != nil {
+		return "", fmt.Errorf("не удалось разобрать длительность %q: %w", args[0], err)
+	}
+
+	b.mute(d)
+
+	return fmt.Sprintf("Уведомления приостановлены на %s, логирование продолжается", d), nil
+}
+
+func handleUnmute(_ context.Context, b *Bot, _ []string) (string, error) {
+	b.unmute()
+	return "Уведомления возобновлены", nil
+}
+
+func handleWhois(ctx context.Context, b *Bot, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("укажите IP, например /whois 8.8.8.8")
+	}
+
+	if b.geoLookup == nil {
+		return "", fmt.Errorf("геолокация недоступна")
+	}
+
+	geo, err := b.geoLookup(ctx, args[0])
+	if err != nil {
+		return "", fmt.Errorf("ошибка геолокации: %w", err)
+	}
+
+	return fmt.Sprintf("%s\nСтрана: %s\nГород: %s\nASN: %s\nПровайдер: %s",
+		args[0], geo.Country, geo.City, geo.ASN, geo.ISP), nil
+}
+
+func handleSetDedup(_ context.Context, b *Bot, args []string) (string, error) {
+	if b.dedupSetter == nil {
+		return "", fmt.Errorf("дедупликация уведомлений не подключена")
+	}
+
+	if len(args) == 0 {
+		return "", fmt.Errorf("укажите длительность, например /setdedup 1h")
+	}
+
+	d, err := time.ParseDuration(args[0])
+	if err != nil {
+		return "", fmt.Errorf("не удалось разобрать длительность %q: %w", args[0], err)
+	}
+
+	b.dedupSetter.SetTTL(d)
+
+	return fmt.Sprintf("Порог повторного уведомления об одном IP: %s", d), nil
+}
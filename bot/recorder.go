@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Event - одно событие синхронизации NTP-клиента, попавшее в бота.
+type Event struct {
+	Time    time.Time
+	IP      string
+	Country string
+	ASN     string
+}
+
+// Stats - агрегированная статистика по событиям за период.
+type Stats struct {
+	Total        int
+	UniqueIPs    int
+	TopCountries []CountedField
+	TopASNs      []CountedField
+}
+
+// CountedField - значение поля и сколько раз оно встретилось.
+type CountedField struct {
+	Value string
+	Count int
+}
+
+// Recorder - источник данных для команд /stats и /recent.
+// Команды бота работают только через этот интерфейс, так что
+// реализацию можно будет подменить постоянным хранилищем позже.
+type Recorder interface {
+	Record(ev Event)
+	Stats(since time.Time) Stats
+	Recent(n int) []Event
+}
+
+// memRecorder - Recorder в памяти процесса: хранит все события с момента
+// запуска (старые не вытесняются) и не переживает перезапуск.
+type memRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemRecorder - создаёт Recorder, хранящий события в памяти.
+func NewMemRecorder() Recorder {
+	return &memRecorder{}
+}
+
+func (r *memRecorder) Record(ev Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func (r *memRecorder) Stats(since time.Time) Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stats Stats
+	ips := make(map[string]struct{})
+	countries := make(map[string]int)
+	asns := make(map[string]int)
+
+	for _, ev := range r.events {
+		if ev.Time.Before(since) {
+			continue
+		}
+		stats.Total++
+		ips[ev.IP] = struct{}{}
+		if ev.Country != "" {
+			countries[ev.Country]++
+		}
+		if ev.ASN != "" {
+			asns[ev.ASN]++
+		}
+	}
+
+	stats.UniqueIPs = len(ips)
+	stats.TopCountries = topN(countries, 5)
+	stats.TopASNs = topN(asns, 5)
+
+	return stats
+}
+
+func (r *memRecorder) Recent(n int) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n <= 0 || n > len(r.events) {
+		n = len(r.events)
+	}
+
+	out := make([]Event, n)
+	copy(out, r.events[len(r.events)-n:])
+
+	// Новые события - в начале списка.
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+
+	return out
+}
+
+func topN(counts map[string]int, n int) []CountedField {
+	fields := make([]CountedField, 0, len(counts))
+	for value, count := range counts {
+		fields = append(fields, CountedField{Value: value, Count: count})
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Count == fields[j].Count {
+			return fields[i].Value < fields[j].Value
+		}
+		return fields[i].Count > fields[j].Count
+	})
+
+	if len(fields) > n {
+		fields = fields[:n]
+	}
+
+	return fields
+}
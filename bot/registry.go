@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Handler - обработчик одной команды. args - слова после имени команды.
+type Handler func(ctx context.Context, b *Bot, args []string) (string, error)
+
+// Command - команда, зарегистрированная в Registry.
+type Command struct {
+	Name    string
+	Usage   string
+	Handler Handler
+}
+
+// Registry - реестр команд бота. Чтобы добавить новую команду,
+// достаточно зарегистрировать её через Register - никакого
+// дополнительного switch/case в коде диспетчера не требуется.
+type Registry struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+// NewRegistry - создаёт пустой реестр команд.
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]Command)}
+}
+
+// Register - добавляет команду в реестр. Имя команды указывается
+// без ведущего слэша, например "stats".
+func (r *Registry) Register(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[cmd.Name] = cmd
+}
+
+// Lookup - возвращает команду по имени.
+func (r *Registry) Lookup(name string) (Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, ok := r.commands[strings.TrimPrefix(name, "/")]
+	return cmd, ok
+}
+
+// Help - список зарегистрированных команд с их usage-строками.
+func (r *Registry) Help() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "/%s - %s\n", name, r.commands[name].Usage)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
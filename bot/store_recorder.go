@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/hermanguilliman/NtpSpy/store"
+)
+
+// StoreRecorder - Recorder поверх постоянного store.Store: в отличие
+// от memRecorder, история /stats и /recent переживает перезапуск
+// процесса. Record - no-op, так как событие уже сохраняется в
+// store.Store.Append на пути обработки UDP-пакета (см. startNTPServer
+// в main.go) - StoreRecorder отвечает только за чтение.
+type StoreRecorder struct {
+	store store.Store
+}
+
+// NewStoreRecorder - создаёт Recorder, читающий историю синхронизаций
+// из s.
+func NewStoreRecorder(s store.Store) *StoreRecorder {
+	return &StoreRecorder{store: s}
+}
+
+func (r *StoreRecorder) Record(Event) {}
+
+func (r *StoreRecorder) Stats(since time.Time) Stats {
+	window := time.Since(since)
+
+	events, err := r.store.RangeByTime(since, time.Now().Add(time.Second))
+	if err != nil {
+		return Stats{}
+	}
+
+	var stats Stats
+	ips := make(map[string]struct{})
+	for _, ev := range events {
+		stats.Total++
+		ips[ev.IP] = struct{}{}
+	}
+	stats.UniqueIPs = len(ips)
+
+	if countries, err := r.store.TopN("country", window, 5); err == nil {
+		stats.TopCountries = toCountedFields(countries)
+	}
+	if asns, err := r.store.TopN("asn", window, 5); err == nil {
+		stats.TopASNs = toCountedFields(asns)
+	}
+
+	return stats
+}
+
+func toCountedFields(fields []store.FieldCount) []CountedField {
+	out := make([]CountedField, len(fields))
+	for i, f := range fields {
+		out[i] = CountedField{Value: f.Value, Count: f.Count}
+	}
+	return out
+}
+
+func (r *StoreRecorder) Recent(n int) []Event {
+	if n <= 0 {
+		n = 10
+	}
+
+	events, err := r.store.RangeByTime(time.Time{}, time.Now().Add(time.Second))
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+
+	if n > len(events) {
+		n = len(events)
+	}
+	tail := events[len(events)-n:]
+
+	out := make([]Event, len(tail))
+	for i, ev := range tail {
+		// Новые события - в начале списка.
+		out[len(tail)-1-i] = Event{Time: ev.Timestamp, IP: ev.IP, Country: ev.Geo.Country, ASN: ev.Geo.ASN}
+	}
+
+	return out
+}
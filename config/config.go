@@ -0,0 +1,142 @@
+// Package config загружает и валидирует YAML-конфигурацию NtpSpy и
+// умеет перечитывать её на лету по SIGHUP.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration - time.Duration, который можно задать в YAML строкой вида "1h30m".
+type Duration time.Duration
+
+// UnmarshalYAML - разбирает строковую длительность в YAML.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw string
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return fmt.Errorf("некорректная длительность %q: %w", raw, err)
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Value - time.Duration для использования в остальном коде.
+func (d Duration) Value() time.Duration { return time.Duration(d) }
+
+// NTPConfig - настройки NTP-сервера.
+type NTPConfig struct {
+	Port string `yaml:"port"`
+}
+
+// TelegramConfig - настройки Telegram-транспорта.
+type TelegramConfig struct {
+	// Transport - "bot" (Bot API) или "mtproto".
+	Transport string `yaml:"transport"`
+	Token     string `yaml:"token"`
+	ChatID    string `yaml:"chat_id"`
+
+	// AppID, AppHash и Phone нужны только при transport: mtproto. Phone
+	// можно не задавать, если Token используется для входа ботом через MTProto.
+	AppID   int32  `yaml:"app_id"`
+	AppHash string `yaml:"app_hash"`
+	Phone   string `yaml:"phone"`
+}
+
+// DedupConfig - пороги схлопывания и повторных уведомлений.
+type DedupConfig struct {
+	Window Duration `yaml:"window"`
+	TTL    Duration `yaml:"ttl"`
+}
+
+// GeoProviderConfig - один провайдер геолокации из секции geo.providers.
+type GeoProviderConfig struct {
+	Name   string `yaml:"name"`
+	APIKey string `yaml:"api_key"`
+}
+
+// GeoConfig - настройки геолокации.
+type GeoConfig struct {
+	Providers []GeoProviderConfig `yaml:"providers"`
+	CacheTTL  Duration            `yaml:"cache_ttl"`
+}
+
+// CommandsConfig - ограничения на Telegram-команды.
+type CommandsConfig struct {
+	// Allowlist - ID чатов, которым разрешено отправлять команды боту.
+	// Пустой список означает "разрешено всем в TelegramConfig.ChatID".
+	Allowlist []string `yaml:"allowlist"`
+}
+
+// Config - корневая конфигурация NtpSpy.
+type Config struct {
+	NTP       NTPConfig      `yaml:"ntp"`
+	Telegram  TelegramConfig `yaml:"telegram"`
+	DataDir   string         `yaml:"data_dir"`
+	Retention Duration       `yaml:"retention"`
+	Dedup     DedupConfig    `yaml:"dedup"`
+	Geo       GeoConfig      `yaml:"geo"`
+	Commands  CommandsConfig `yaml:"commands"`
+}
+
+// Load - читает YAML-файл по path, валидирует его по встроенной JSON
+// Schema и применяет переопределения секретов из переменных окружения.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать конфигурацию %s: %w", path, err)
+	}
+
+	if err := validateSchema(data); err != nil {
+		return nil, fmt.Errorf("конфигурация %s не прошла валидацию: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать конфигурацию %s: %w", path, err)
+	}
+
+	applyEnvOverrides(&cfg)
+
+	return &cfg, nil
+}
+
+// applyEnvOverrides - секреты живут в окружении, а не в файле на диске.
+func applyEnvOverrides(cfg *Config) {
+	if token := os.Getenv("TELEGRAM_TOKEN"); token != "" {
+		cfg.Telegram.Token = token
+	}
+
+	for i := range cfg.Geo.Providers {
+		envName := "NTPSPY_GEO_" + cfg.Geo.Providers[i].Name + "_API_KEY"
+		if key := os.Getenv(envName); key != "" {
+			cfg.Geo.Providers[i].APIKey = key
+		}
+	}
+}
+
+// validateSchema - валидирует YAML-документ против embedded JSON Schema.
+// YAML приводится к JSON, так как yaml.v3 при разборе в interface{}
+// уже отдаёт map[string]interface{}, совместимый с encoding/json.
+func validateSchema(yamlData []byte) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(yamlData, &doc); err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return validateAgainstSchema(jsonData)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// ReloadFunc - колбэк, вызываемый после успешного Reload с новой
+// конфигурацией, чтобы живые потребители (dedup, хранилище, кэш гео)
+// подхватили изменившиеся настройки без перезапуска процесса.
+type ReloadFunc func(cfg *Config)
+
+// Manager - держит текущую конфигурацию и умеет атомарно подменить её
+// по SIGHUP, не трогая уже запущенные UDP-сокет и long-polling бота.
+type Manager struct {
+	path   string
+	logger *zap.Logger
+
+	mu    sync.RWMutex
+	cfg   *Config
+	hooks []ReloadFunc
+}
+
+// NewManager - загружает конфигурацию из path и возвращает Manager,
+// готовый отдавать её через Current и перечитывать через Reload.
+func NewManager(path string, logger *zap.Logger) (*Manager, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{path: path, logger: logger, cfg: cfg}, nil
+}
+
+// Current - текущая конфигурация. Вызывающий код не должен мутировать
+// возвращённое значение.
+func (m *Manager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cfg
+}
+
+// OnReload - подписывает fn на успешные перечитывания конфигурации.
+// fn вызывается синхронно из Reload с уже новой конфигурацией, после
+// того как m.cfg атомарно подменён.
+func (m *Manager) OnReload(fn ReloadFunc) {
+	m.mu.Lock()
+	m.hooks = append(m.hooks, fn)
+	m.mu.Unlock()
+}
+
+// Reload - перечитывает файл конфигурации и, если он валиден, атомарно
+// подменяет текущую конфигурацию, затем прогоняет её через хуки,
+// зарегистрированные через OnReload.
+func (m *Manager) Reload() error {
+	cfg, err := Load(m.path)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.cfg = cfg
+	hooks := append([]ReloadFunc(nil), m.hooks...)
+	m.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(cfg)
+	}
+
+	return nil
+}
+
+// WatchReloadSignal - перечитывает конфигурацию при получении SIGHUP,
+// пока не будет отменён ctx.
+func (m *Manager) WatchReloadSignal(ctx context.Context) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigChan:
+			if err := m.Reload(); err != nil {
+				m.logger.Warn("Не удалось перечитать конфигурацию по SIGHUP", zap.String("path", m.path), zap.Error(err))
+				continue
+			}
+			m.logger.Info("Конфигурация перечитана по SIGHUP", zap.String("path", m.path))
+		}
+	}
+}
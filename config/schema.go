@@ -0,0 +1,31 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//go:embed config_schema.json
+var schemaJSON []byte
+
+func validateAgainstSchema(documentJSON []byte) error {
+	schemaLoader := gojsonschema.NewBytesLoader(schemaJSON)
+	documentLoader := gojsonschema.NewBytesLoader(documentJSON)
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return fmt.Errorf("не удалось выполнить валидацию по схеме: %w", err)
+	}
+
+	if !result.Valid() {
+		var msg string
+		for _, e := range result.Errors() {
+			msg += e.String() + "; "
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cacheEntry - закэшированный результат (успешный или нет) с
+// временем, до которого он считается свежим.
+type cacheEntry struct {
+	geo       GeoIP
+	err       error
+	expiresAt time.Time
+}
+
+// negativeCacheTTL - TTL для негативного кэширования ошибок провайдера.
+// Берётся заметно короче успешного ttl, чтобы одна временная ошибка
+// (например, 429 от провайдера) не глушила геолокацию по IP на весь
+// срок жизни успешной записи.
+const negativeCacheTTL = 30 * time.Second
+
+// CachingProvider - Provider с TTL-кэшем по IP, включая негативное
+// кэширование ошибок, чтобы падающий провайдер не дёргался на каждый пакет.
+type CachingProvider struct {
+	next Provider
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewCachingProvider - оборачивает next кэшем с TTL ttl (по умолчанию 24 часа).
+func NewCachingProvider(next Provider, ttl time.Duration) *CachingProvider {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &CachingProvider{next: next, ttl: ttl, cache: make(map[string]cacheEntry)}
+}
+
+func (c *CachingProvider) Name() string { return c.next.Name() }
+
+// SetCacheTTL - меняет TTL кэша на лету (например, при перечитывании
+// конфигурации по SIGHUP). Уже закэшированные записи донашивают
+// прежний TTL, обновление применяется к новым.
+func (c *CachingProvider) SetCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+}
+
+func (c *CachingProvider) Lookup(ctx context.Context, ip string) (GeoIP, error) {
+	c.mu.Lock()
+	entry, ok := c.cache[ip]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.geo, entry.err
+	}
+
+	geoResult, err := c.next.Lookup(ctx, ip)
+
+	ttl := c.ttl
+	if err != nil {
+		ttl = negativeCacheTTL
+	}
+
+	c.mu.Lock()
+	c.cache[ip] = cacheEntry{geo: geoResult, err: err, expiresAt: time.Now().Add(ttl)}
+	c.mu.Unlock()
+
+	return geoResult, err
+}
@@ -0,0 +1,43 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FallbackProvider - пробует провайдеров по очереди, пока один не ответит успешно.
+type FallbackProvider struct {
+	providers []Provider
+}
+
+// NewFallbackProvider - создаёт провайдера, пробующего providers по порядку.
+func NewFallbackProvider(providers ...Provider) *FallbackProvider {
+	return &FallbackProvider{providers: providers}
+}
+
+func (f *FallbackProvider) Name() string {
+	names := make([]string, len(f.providers))
+	for i, p := range f.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "->")
+}
+
+func (f *FallbackProvider) Lookup(ctx context.Context, ip string) (GeoIP, error) {
+	var lastErr error
+
+	for _, p := range f.providers {
+		geoResult, err := p.Lookup(ctx, ip)
+		if err == nil {
+			return geoResult, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("нет ни одного настроенного провайдера геолокации")
+	}
+
+	return GeoIP{}, fmt.Errorf("все провайдеры геолокации вернули ошибку, последняя: %w", lastErr)
+}
@@ -0,0 +1,22 @@
+// Package geo предоставляет геолокацию по IP через несколько
+// провайдеров с кэшированием и фоновый пул воркеров, чтобы медленный
+// провайдер не блокировал обработку NTP-пакетов.
+package geo
+
+import "context"
+
+// GeoIP - геоданные по IP-адресу.
+type GeoIP struct {
+	Country string
+	City    string
+	ASN     string
+	ISP     string
+}
+
+// Provider - источник геолокации по IP.
+type Provider interface {
+	// Name - имя провайдера для логов и сообщений об ошибках.
+	Name() string
+	// Lookup - геоданные по IP.
+	Lookup(ctx context.Context, ip string) (GeoIP, error)
+}
@@ -0,0 +1,69 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// IPAPIProvider - Provider поверх ip-api.com.
+type IPAPIProvider struct {
+	client *http.Client
+	limit  rateLimiter
+}
+
+// NewIPAPIProvider - создаёт провайдера на базе ip-api.com.
+func NewIPAPIProvider(client *http.Client) *IPAPIProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &IPAPIProvider{client: client}
+}
+
+func (p *IPAPIProvider) Name() string { return "ip-api.com" }
+
+func (p *IPAPIProvider) Lookup(ctx context.Context, ip string) (GeoIP, error) {
+	if err := p.limit.allow(); err != nil {
+		return GeoIP{}, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+
+	endpoint := fmt.Sprintf("http://ip-api.com/json/%s?fields=country,city,as,isp,status,message", ip)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return GeoIP{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return GeoIP{}, err
+	}
+	defer resp.Body.Close()
+
+	// ip-api.com отдаёт остаток квоты в X-Rl и окно сброса в X-Ttl секундах.
+	p.limit.update(resp.Header, "X-Rl", "X-Ttl")
+
+	if resp.StatusCode != http.StatusOK {
+		return GeoIP{}, fmt.Errorf("%s вернул ошибку: %s", p.Name(), resp.Status)
+	}
+
+	var body struct {
+		Country string `json:"country"`
+		City    string `json:"city"`
+		ASN     string `json:"as"`
+		ISP     string `json:"isp"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return GeoIP{}, err
+	}
+
+	if body.Status == "fail" {
+		return GeoIP{}, fmt.Errorf("%s: %s", p.Name(), body.Message)
+	}
+
+	return GeoIP{Country: body.Country, City: body.City, ASN: body.ASN, ISP: body.ISP}, nil
+}
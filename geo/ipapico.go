@@ -0,0 +1,70 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// IPAPICoProvider - Provider поверх ipapi.co.
+type IPAPICoProvider struct {
+	client *http.Client
+	limit  rateLimiter
+}
+
+// NewIPAPICoProvider - создаёт провайдера на базе ipapi.co.
+func NewIPAPICoProvider(client *http.Client) *IPAPICoProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &IPAPICoProvider{client: client}
+}
+
+func (p *IPAPICoProvider) Name() string { return "ipapi.co" }
+
+func (p *IPAPICoProvider) Lookup(ctx context.Context, ip string) (GeoIP, error) {
+	if err := p.limit.allow(); err != nil {
+		return GeoIP{}, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+
+	endpoint := fmt.Sprintf("https://ipapi.co/%s/json/", ip)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return GeoIP{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return GeoIP{}, err
+	}
+	defer resp.Body.Close()
+
+	// ipapi.co отдаёт остаток дневной квоты в X-RateLimit-Remaining и
+	// время до сброса в X-RateLimit-Reset секундах.
+	p.limit.update(resp.Header, "X-RateLimit-Remaining", "X-RateLimit-Reset")
+
+	if resp.StatusCode != http.StatusOK {
+		return GeoIP{}, fmt.Errorf("%s вернул ошибку: %s", p.Name(), resp.Status)
+	}
+
+	var body struct {
+		Country string `json:"country_name"`
+		City    string `json:"city"`
+		ASN     string `json:"asn"`
+		ISP     string `json:"org"`
+		Error   bool   `json:"error"`
+		Reason  string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return GeoIP{}, err
+	}
+
+	if body.Error {
+		return GeoIP{}, fmt.Errorf("%s: %s", p.Name(), body.Reason)
+	}
+
+	return GeoIP{Country: body.Country, City: body.City, ASN: body.ASN, ISP: body.ISP}, nil
+}
@@ -0,0 +1,83 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IPInfoProvider - Provider поверх ipinfo.io.
+type IPInfoProvider struct {
+	client *http.Client
+	token  string
+	limit  rateLimiter
+}
+
+// NewIPInfoProvider - создаёт провайдера на базе ipinfo.io. token может
+// быть пустым для неавторизованного (сильно урезанного) доступа.
+func NewIPInfoProvider(client *http.Client, token string) *IPInfoProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &IPInfoProvider{client: client, token: token}
+}
+
+func (p *IPInfoProvider) Name() string { return "ipinfo.io" }
+
+func (p *IPInfoProvider) Lookup(ctx context.Context, ip string) (GeoIP, error) {
+	if err := p.limit.allow(); err != nil {
+		return GeoIP{}, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+
+	endpoint := fmt.Sprintf("https://ipinfo.io/%s/json", ip)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return GeoIP{}, err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return GeoIP{}, err
+	}
+	defer resp.Body.Close()
+
+	// ipinfo.io отдаёт остаток дневной квоты в X-RateLimit-Remaining и
+	// время до сброса в X-RateLimit-Reset секундах.
+	p.limit.update(resp.Header, "X-RateLimit-Remaining", "X-RateLimit-Reset")
+
+	if resp.StatusCode != http.StatusOK {
+		return GeoIP{}, fmt.Errorf("%s вернул ошибку: %s", p.Name(), resp.Status)
+	}
+
+	var body struct {
+		City string `json:"city"`
+		// Country - код страны (например "US"); ipinfo.io не отдаёт
+		// полное название бесплатно, в отличие от ip-api.com и ipapi.co,
+		// которые возвращают название страны целиком.
+		Country string `json:"country"`
+		Org     string `json:"org"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return GeoIP{}, err
+	}
+
+	// Поле org в ipinfo.io - вида "AS15169 Google LLC".
+	asn, isp := splitOrg(body.Org)
+
+	return GeoIP{Country: body.Country, City: body.City, ASN: asn, ISP: isp}, nil
+}
+
+func splitOrg(org string) (asn, isp string) {
+	parts := strings.SplitN(org, " ", 2)
+	if len(parts) == 2 && strings.HasPrefix(parts[0], "AS") {
+		return parts[0], parts[1]
+	}
+	return "", org
+}
@@ -0,0 +1,69 @@
+package geo
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// job - одна отложенная геолокация: IP и куда вернуть результат.
+type job struct {
+	ctx    context.Context
+	ip     string
+	result func(GeoIP, error)
+}
+
+// Pool - пул воркеров, выполняющих Provider.Lookup в фоне, чтобы
+// медленный провайдер не блокировал чтение UDP-пакетов.
+type Pool struct {
+	provider Provider
+	jobs     chan job
+	logger   *zap.Logger
+}
+
+// NewPool - запускает workers воркеров, читающих из очереди длиной queueSize.
+func NewPool(provider Provider, workers, queueSize int, logger *zap.Logger) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	p := &Pool{
+		provider: provider,
+		jobs:     make(chan job, queueSize),
+		logger:   logger,
+	}
+
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+
+	return p
+}
+
+func (p *Pool) worker() {
+	for j := range p.jobs {
+		geoResult, err := p.provider.Lookup(j.ctx, j.ip)
+		j.result(geoResult, err)
+	}
+}
+
+// Submit - ставит IP в очередь на геолокацию. Если очередь заполнена,
+// запрос отбрасывается и result вызывается с ошибкой - NTP-ответ
+// клиенту при этом уже отправлен и от геолокации не зависит.
+func (p *Pool) Submit(ctx context.Context, ip string, result func(GeoIP, error)) {
+	select {
+	case p.jobs <- job{ctx: ctx, ip: ip, result: result}:
+	default:
+		p.logger.Warn("Очередь геолокации переполнена, запрос отброшен", zap.String("ip", ip))
+		result(GeoIP{}, errQueueFull)
+	}
+}
+
+var errQueueFull = poolError("очередь геолокации переполнена")
+
+type poolError string
+
+func (e poolError) Error() string { return string(e) }
@@ -0,0 +1,57 @@
+package geo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter - отслеживает оставшуюся квоту запросов провайдера по
+// заголовкам его ответов и не даёт стучаться дальше, если квота исчерпана.
+type rateLimiter struct {
+	mu        sync.Mutex
+	remaining int
+	resetAt   time.Time
+	known     bool
+}
+
+// allow - можно ли делать запрос прямо сейчас.
+func (r *rateLimiter) allow() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.known && r.remaining <= 0 && time.Now().Before(r.resetAt) {
+		return fmt.Errorf("лимит запросов исчерпан, сброс через %s", time.Until(r.resetAt).Round(time.Second))
+	}
+
+	return nil
+}
+
+// update - обновляет оставшуюся квоту по заголовкам remainingHeader и
+// resetHeader (значение resetHeader - секунды до сброса).
+func (r *rateLimiter) update(h http.Header, remainingHeader, resetHeader string) {
+	remaining := h.Get(remainingHeader)
+	reset := h.Get(resetHeader)
+	if remaining == "" {
+		return
+	}
+
+	n, err := strconv.Atoi(remaining)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.known = true
+	r.remaining = n
+
+	if reset != "" {
+		if secs, err := strconv.Atoi(reset); err == nil {
+			r.resetAt = time.Now().Add(time.Duration(secs) * time.Second)
+		}
+	}
+}
@@ -2,35 +2,49 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"net"
-	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/hermanguilliman/NtpSpy/bot"
+	"github.com/hermanguilliman/NtpSpy/config"
+	"github.com/hermanguilliman/NtpSpy/geo"
+	"github.com/hermanguilliman/NtpSpy/notifier"
+	"github.com/hermanguilliman/NtpSpy/notify"
+	"github.com/hermanguilliman/NtpSpy/store"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
 
-// Config - структура для конфигурации программы
-type Config struct {
-	NTPPort        string
-	TelegramToken  string
-	TelegramChatID string
-}
-
-// GeoIP - структура для хранения геолокационных данных
-type GeoIP struct {
-	Country string `json:"country"`
-	City    string `json:"city"`
-	ASN     string `json:"as"`
-	ISP     string `json:"isp"`
-}
+const (
+	// defaultConfigPath - путь к конфигурации, если не передан флаг -config.
+	defaultConfigPath = "/etc/ntpspy/config.yml"
+	// defaultDedupWindow - окно схлопывания всплесков повторных пакетов от
+	// одного IP в одно сводное сообщение, если dedup.window не задан в конфиге.
+	defaultDedupWindow = 1 * time.Minute
+	// defaultDedupTTL - через сколько простоя снова уведомлять про тот же
+	// IP, если dedup.ttl не задан в конфиге.
+	defaultDedupTTL = 1 * time.Hour
+	// defaultGeoCacheTTL - срок жизни закэшированного результата геолокации,
+	// если geo.cache_ttl не задан в конфиге.
+	defaultGeoCacheTTL = 24 * time.Hour
+	// defaultDataDir - каталог данных, если data_dir не задан в конфиге.
+	defaultDataDir = "./data"
+	// geoWorkers - число воркеров, обслуживающих очередь геолокации.
+	geoWorkers = 4
+	// geoQueueSize - размер очереди геолокации перед воркерами.
+	geoQueueSize = 256
+	// botAPIErrBackoff - пауза перед повтором getUpdates после ошибки
+	// для транспорта Bot API (сам long-poll блокируется на стороне Telegram).
+	botAPIErrBackoff = 1 * time.Second
+	// botAPIOffsetPath - файл с сохранённым offset getUpdates.
+	botAPIOffsetPath = "ntpspy_offset.txt"
+)
 
 var logger *zap.Logger
 
@@ -46,26 +60,60 @@ func init() {
 func main() {
 	_ = godotenv.Load()
 
-	config := Config{
-		NTPPort:        os.Getenv("NTP_PORT"),
-		TelegramToken:  os.Getenv("TELEGRAM_TOKEN"),
-		TelegramChatID: os.Getenv("TELEGRAM_CHAT_ID"),
+	configPath := flag.String("config", defaultConfigPath, "путь к YAML-конфигурации NtpSpy")
+	flag.Parse()
+
+	cfgManager, err := config.NewManager(*configPath, logger)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить конфигурацию", zap.String("path", *configPath), zap.Error(err))
 	}
+	cfg := cfgManager.Current()
 
-	if config.NTPPort == "" || config.TelegramToken == "" || config.TelegramChatID == "" {
-		logger.Fatal("Отсутствуют обязательные переменные окружения: NTP_PORT, TELEGRAM_TOKEN, TELEGRAM_CHAT_ID")
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = defaultDataDir
 	}
 
+	eventStore, err := store.Open(dataDir, cfg.Retention.Value(), logger)
+	if err != nil {
+		logger.Fatal("Не удалось открыть хранилище событий", zap.Error(err))
+	}
+	defer eventStore.Close()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	msgChan := make(chan string, 100)
-	go processTelegramMessages(ctx, config.TelegramToken, config.TelegramChatID, msgChan)
-
-	go startNTPServer(ctx, config.NTPPort, msgChan)
+	geoProvider := geo.NewCachingProvider(newGeoProviderChain(cfg.Geo.Providers), valueOrDefault(cfg.Geo.CacheTTL.Value(), defaultGeoCacheTTL))
+	geoPool := geo.NewPool(geoProvider, geoWorkers, geoQueueSize, logger)
+
+	sink := newTelegramSink(cfg.Telegram, eventStore, logger)
+	tgBot := bot.NewBot(sink, cfg.Telegram.ChatID, logger,
+		bot.WithGeoLookup(geoLookupAdapter(geoProvider)),
+		bot.WithRecorder(bot.NewStoreRecorder(eventStore)),
+		bot.WithAllowlist(cfg.Commands.Allowlist),
+	)
+	go tgBot.Listen(ctx)
+
+	dedup := notify.New(
+		tgBot,
+		valueOrDefault(cfg.Dedup.Window.Value(), defaultDedupWindow),
+		valueOrDefault(cfg.Dedup.TTL.Value(), defaultDedupTTL),
+		logger,
+	)
+	tgBot.SetDedupSetter(dedup)
+
+	cfgManager.OnReload(func(cfg *config.Config) {
+		dedup.SetWindow(valueOrDefault(cfg.Dedup.Window.Value(), defaultDedupWindow))
+		dedup.SetTTL(valueOrDefault(cfg.Dedup.TTL.Value(), defaultDedupTTL))
+		eventStore.SetRetention(cfg.Retention.Value())
+		geoProvider.SetCacheTTL(valueOrDefault(cfg.Geo.CacheTTL.Value(), defaultGeoCacheTTL))
+	})
+	go cfgManager.WatchReloadSignal(ctx)
+
+	go startNTPServer(ctx, cfg.NTP.Port, tgBot, eventStore, dedup, geoPool)
 
 	logger.Info("Программа запущена")
 
@@ -77,30 +125,71 @@ func main() {
 	logger.Info("Программа завершена")
 }
 
-// getGeoIP - функция для получения геолокационных данных по IP-адресу
-func getGeoIP(ip string) (GeoIP, error) {
-	var geo GeoIP
-	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=country,city,as,isp", ip)
-	resp, err := http.Get(url)
-	if err != nil {
-		return geo, err
+// valueOrDefault - value, если он задан (не ноль), иначе def.
+func valueOrDefault(value, def time.Duration) time.Duration {
+	if value == 0 {
+		return def
 	}
-	defer resp.Body.Close()
+	return value
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return geo, fmt.Errorf("API вернул ошибку: %s", resp.Status)
+// newGeoProviderChain - строит цепочку провайдеров геолокации из секции
+// geo.providers конфигурации. Пустая секция означает "все известные
+// провайдеры в порядке по умолчанию".
+func newGeoProviderChain(providers []config.GeoProviderConfig) geo.Provider {
+	if len(providers) == 0 {
+		return geo.NewFallbackProvider(
+			geo.NewIPAPIProvider(nil),
+			geo.NewIPInfoProvider(nil, ""),
+			geo.NewIPAPICoProvider(nil),
+		)
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&geo)
-	if err != nil {
-		return geo, err
+	chain := make([]geo.Provider, 0, len(providers))
+	for _, p := range providers {
+		switch p.Name {
+		case "ip-api.com":
+			chain = append(chain, geo.NewIPAPIProvider(nil))
+		case "ipinfo.io":
+			chain = append(chain, geo.NewIPInfoProvider(nil, p.APIKey))
+		case "ipapi.co":
+			chain = append(chain, geo.NewIPAPICoProvider(nil))
+		default:
+			logger.Warn("Неизвестный провайдер геолокации в конфигурации, пропущен", zap.String("name", p.Name))
+		}
 	}
 
-	return geo, nil
+	return geo.NewFallbackProvider(chain...)
+}
+
+// newTelegramSink - выбирает транспорт Telegram по telegram.transport:
+// "bot" (по умолчанию) использует Bot API, "mtproto" - пользовательский
+// MTProto-клиент с сессией, сохраняемой в eventStore.
+func newTelegramSink(cfg config.TelegramConfig, sessions store.SessionStore, logger *zap.Logger) notifier.Sink {
+	switch cfg.Transport {
+	case "mtproto":
+		return notifier.NewMTProtoSink(cfg.AppID, cfg.AppHash, cfg.Token, cfg.Phone, cfg.ChatID, sessions, logger)
+	default:
+		return notifier.NewBotAPISink(cfg.Token, cfg.ChatID, botAPIOffsetPath, botAPIErrBackoff, logger)
+	}
+}
+
+// geoLookupAdapter - адаптер geo.Provider к bot.GeoLookupFunc для команды
+// /whois: запрос разовый и не на пути обработки UDP-пакетов, поэтому
+// выполняется синхронно, в обход пула воркеров.
+func geoLookupAdapter(provider geo.Provider) bot.GeoLookupFunc {
+	return func(ctx context.Context, ip string) (bot.GeoResult, error) {
+		result, err := provider.Lookup(ctx, ip)
+		if err != nil {
+			return bot.GeoResult{}, err
+		}
+
+		return bot.GeoResult{Country: result.Country, City: result.City, ASN: result.ASN, ISP: result.ISP}, nil
+	}
 }
 
 // startNTPServer - запуск NTP-сервера с обработкой запросов и геолокацией
-func startNTPServer(ctx context.Context, port string, msgChan chan<- string) {
+func startNTPServer(ctx context.Context, port string, tgBot *bot.Bot, eventStore store.Store, dedup *notify.Notifier, geoPool *geo.Pool) {
 	addr, err := net.ResolveUDPAddr("udp", ":"+port)
 	if err != nil {
 		logger.Fatal("Ошибка настройки адреса", zap.Error(err))
@@ -143,24 +232,35 @@ func startNTPServer(ctx context.Context, port string, msgChan chan<- string) {
 
 					// Извлекаем IP-адрес клиента
 					ip := clientAddr.IP.String()
-
-					// Получаем геолокационные данные
-					geo, err := getGeoIP(ip)
-					var msg string
-					if err != nil {
-						logger.Warn("Ошибка получения геолокационных данных", zap.Error(err))
-						msg = fmt.Sprintf("Синхронизация NTP с клиентом: %s (геолокация не определена)", ip)
-					} else {
-						msg = fmt.Sprintf("Синхронизация NTP с клиентом: %s\nСтрана: %s\nГород: %s\nASN: %s\nПровайдер: %s",
-							ip, geo.Country, geo.City, geo.ASN, geo.ISP)
-					}
-
-					// Отправляем сообщение в канал
-					select {
-					case msgChan <- msg:
-					default:
-						logger.Warn("Очередь сообщений переполнена, сообщение отброшено")
-					}
+					raw := append([]byte{}, buf[:n]...)
+					now := time.Now()
+
+					// Геолокация уходит в пул воркеров и не блокирует чтение
+					// следующего UDP-пакета - NTP-ответ клиенту уже отправлен.
+					geoPool.Submit(ctx, ip, func(geoResult geo.GeoIP, geoErr error) {
+						var msg, geoHash string
+						if geoErr != nil {
+							logger.Warn("Ошибка получения геолокационных данных", zap.Error(geoErr))
+							msg = fmt.Sprintf("Синхронизация NTP с клиентом: %s (геолокация не определена)", ip)
+						} else {
+							msg = fmt.Sprintf("Синхронизация NTP с клиентом: %s\nСтрана: %s\nГород: %s\nASN: %s\nПровайдер: %s",
+								ip, geoResult.Country, geoResult.City, geoResult.ASN, geoResult.ISP)
+							geoHash = geoResult.Country + "/" + geoResult.ASN
+						}
+
+						if err := eventStore.Append(store.Event{
+							Timestamp: now,
+							IP:        ip,
+							Mode:      mode,
+							Raw:       raw,
+							Geo:       store.GeoIP{Country: geoResult.Country, City: geoResult.City, ASN: geoResult.ASN, ISP: geoResult.ISP},
+						}); err != nil {
+							logger.Warn("Ошибка сохранения события в хранилище", zap.Error(err))
+						}
+
+						tgBot.Record(bot.Event{Time: now, IP: ip, Country: geoResult.Country, ASN: geoResult.ASN})
+						dedup.Evaluate(ip, geoHash, msg)
+					})
 				} else {
 					logger.Info("Получен некорректный NTP-запрос",
 						zap.String("client", clientAddr.String()),
@@ -197,39 +297,3 @@ func makeNTPResponse() []byte {
 
 	return response
 }
-
-func processTelegramMessages(ctx context.Context, token, chatID string, msgChan <-chan string) {
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Остановка обработки сообщений Telegram")
-			return
-		case msg := <-msgChan:
-			// Кодируем текст сообщения для URL
-			text := url.QueryEscape(msg)
-			urlStr := fmt.Sprintf(
-				"https://api.telegram.org/bot%s/sendMessage?chat_id=%s&text=%s",
-				token,
-				chatID,
-				text,
-			)
-
-			resp, err := http.Get(urlStr)
-			if err != nil {
-				logger.Warn("Ошибка отправки сообщения в Telegram",
-					zap.Error(err),
-					zap.String("url", urlStr),
-				)
-				continue
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				logger.Warn("Telegram API вернул ошибку",
-					zap.String("status", resp.Status),
-					zap.String("url", urlStr),
-				)
-			}
-		}
-	}
-}
@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// longPollTimeoutSeconds - серверный timeout getUpdates: запрос
+// блокируется на стороне Telegram до появления апдейта или истечения
+// этого времени, вместо того чтобы дёргать API короткими интервалами.
+const longPollTimeoutSeconds = 30
+
+// BotAPISink - Sink поверх Telegram Bot API (api.telegram.org). Лимит
+// Bot API - 30 сообщений в секунду глобально и только чаты, где бот
+// состоит участником.
+type BotAPISink struct {
+	token  string
+	chatID string
+	client *http.Client
+	logger *zap.Logger
+
+	// errBackoff - пауза перед повторной попыткой getUpdates после ошибки.
+	errBackoff time.Duration
+	offsetPath string
+	offset     int64
+
+	handlers []Handler
+}
+
+// NewBotAPISink - создаёт Sink для Bot API. offsetPath - файл, в
+// котором сохраняется offset getUpdates между перезапусками. errBackoff -
+// пауза перед повторной попыткой getUpdates после ошибки сети/API.
+func NewBotAPISink(token, chatID, offsetPath string, errBackoff time.Duration, logger *zap.Logger) *BotAPISink {
+	return &BotAPISink{
+		token:      token,
+		chatID:     chatID,
+		client:     &http.Client{Timeout: (longPollTimeoutSeconds + 5) * time.Second},
+		logger:     logger,
+		errBackoff: errBackoff,
+		offsetPath: offsetPath,
+		offset:     loadOffset(offsetPath, logger),
+	}
+}
+
+func (s *BotAPISink) Subscribe(handler Handler) {
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *BotAPISink) Send(ctx context.Context, msg string) error {
+	endpoint := fmt.Sprintf(
+		"https://api.telegram.org/bot%s/sendMessage?chat_id=%s&text=%s",
+		s.token,
+		s.chatID,
+		url.QueryEscape(msg),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Telegram API вернул ошибку: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Run - вызывает getUpdates в цикле с серверным long-poll timeout, так
+// что каждый запрос блокируется до апдейта или longPollTimeoutSeconds,
+// а не дёргает API короткими интервалами.
+func (s *BotAPISink) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		updates, err := s.getUpdates(ctx)
+		if err != nil {
+			s.logger.Warn("Ошибка получения обновлений Telegram", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(s.errBackoff):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			s.offset = u.UpdateID + 1
+			saveOffset(s.offsetPath, s.offset, s.logger)
+
+			if u.Message == nil || u.Message.Text == "" {
+				continue
+			}
+
+			incoming := Incoming{ChatID: fmt.Sprintf("%d", u.Message.Chat.ID), Text: u.Message.Text}
+			for _, h := range s.handlers {
+				h(incoming)
+			}
+		}
+	}
+}
+
+type apiUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+type getUpdatesResponse struct {
+	OK     bool        `json:"ok"`
+	Result []apiUpdate `json:"result"`
+}
+
+func (s *BotAPISink) getUpdates(ctx context.Context) ([]apiUpdate, error) {
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?offset=%d&timeout=%d", s.token, s.offset, longPollTimeoutSeconds)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Telegram API вернул ошибку: %s", resp.Status)
+	}
+
+	var parsed getUpdatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	if !parsed.OK {
+		return nil, fmt.Errorf("Telegram API сообщил об ошибке запроса getUpdates")
+	}
+
+	return parsed.Result, nil
+}
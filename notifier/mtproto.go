@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/amarnathcjd/gogram/telegram"
+	"github.com/hermanguilliman/NtpSpy/store"
+	"go.uber.org/zap"
+)
+
+const mtprotoSessionName = "mtproto"
+
+// MTProtoSink - Sink поверх MTProto-клиента. В отличие от Bot API даёт
+// доступ к пользовательским чатам и держит заметно более высокий
+// лимит сообщений в секунду, но требует либо токена бота, либо логина
+// по номеру телефона.
+type MTProtoSink struct {
+	appID    int32
+	appHash  string
+	botToken string
+	phone    string
+	chatID   string
+
+	sessions store.SessionStore
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	client   *telegram.Client
+	handlers []Handler
+}
+
+// NewMTProtoSink - создаёт Sink на базе MTProto. Если botToken задан,
+// используется ConnectBot, иначе - Login по номеру phone. sessions
+// используется для переживания рестартов без повторного логина.
+func NewMTProtoSink(appID int32, appHash, botToken, phone, chatID string, sessions store.SessionStore, logger *zap.Logger) *MTProtoSink {
+	return &MTProtoSink{
+		appID:    appID,
+		appHash:  appHash,
+		botToken: botToken,
+		phone:    phone,
+		chatID:   chatID,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+func (s *MTProtoSink) Subscribe(handler Handler) {
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *MTProtoSink) Send(ctx context.Context, msg string) error {
+	client := s.getClient()
+	if client == nil {
+		return fmt.Errorf("MTProto-клиент ещё не подключён")
+	}
+
+	_, err := client.SendMessage(s.chatID, msg)
+	return err
+}
+
+func (s *MTProtoSink) getClient() *telegram.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client
+}
+
+func (s *MTProtoSink) setClient(client *telegram.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+func (s *MTProtoSink) Run(ctx context.Context) error {
+	session, ok, err := s.sessions.LoadSession(mtprotoSessionName)
+	if err != nil {
+		return fmt.Errorf("не удалось загрузить сессию MTProto: %w", err)
+	}
+
+	client, err := telegram.NewClient(&telegram.ClientConfig{
+		AppID:         s.appID,
+		AppHash:       s.appHash,
+		StringSession: string(session),
+	})
+	if err != nil {
+		return fmt.Errorf("не удалось создать MTProto-клиент: %w", err)
+	}
+	s.setClient(client)
+
+	if s.botToken != "" {
+		if err := client.ConnectBot(s.botToken); err != nil {
+			return fmt.Errorf("не удалось подключиться как бот через MTProto: %w", err)
+		}
+	} else {
+		if err := client.Login(s.phone); err != nil {
+			return fmt.Errorf("не удалось выполнить логин MTProto по номеру телефона: %w", err)
+		}
+	}
+
+	if !ok {
+		s.logger.Info("Выполнен первый логин MTProto, сохраняем сессию")
+	}
+	if exported, err := client.ExportSession(); err == nil {
+		if err := s.sessions.SaveSession(mtprotoSessionName, []byte(exported)); err != nil {
+			s.logger.Warn("Не удалось сохранить сессию MTProto", zap.Error(err))
+		}
+	}
+
+	client.AddMessageHandler(telegram.OnNewMessage, func(m *telegram.NewMessage) error {
+		incoming := Incoming{ChatID: fmt.Sprintf("%d", m.ChatID()), Text: m.Text()}
+		for _, h := range s.handlers {
+			h(incoming)
+		}
+		return nil
+	})
+
+	<-ctx.Done()
+	client.Stop()
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// loadOffset - читает сохранённый offset getUpdates, чтобы перезапуск
+// бота не приводил к повторной обработке уже прочитанных сообщений.
+// Отсутствие файла - обычная ситуация при первом запуске.
+func loadOffset(path string, logger *zap.Logger) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		logger.Warn("Не удалось разобрать сохранённый offset, начинаем с нуля", zap.String("path", path), zap.Error(err))
+		return 0
+	}
+
+	return offset
+}
+
+// saveOffset - сохраняет offset getUpdates на диск.
+func saveOffset(path string, offset int64, logger *zap.Logger) {
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(offset, 10)), 0o600); err != nil {
+		logger.Warn("Не удалось сохранить offset", zap.String("path", path), zap.Error(err))
+	}
+}
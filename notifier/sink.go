@@ -0,0 +1,30 @@
+// Package notifier абстрагирует транспорт, которым NtpSpy обменивается
+// сообщениями с Telegram: Bot API или MTProto-клиент, и одно и то же
+// дерево команд работает поверх любого из них.
+package notifier
+
+import "context"
+
+// Incoming - входящее текстовое сообщение из чата.
+type Incoming struct {
+	ChatID string
+	Text   string
+}
+
+// Handler - обработчик входящих сообщений, вызываемый Sink.Run.
+type Handler func(Incoming)
+
+// Sink - транспорт доставки и приёма сообщений одного чата/бота.
+// Реализуется Bot API (см. BotAPISink) и MTProto (см. MTProtoSink),
+// выбор делается конфигурацией telegram.transport.
+type Sink interface {
+	// Send - отправляет текстовое сообщение в настроенный чат.
+	Send(ctx context.Context, msg string) error
+
+	// Subscribe - регистрирует обработчик входящих сообщений. Должен
+	// вызываться до Run.
+	Subscribe(handler Handler)
+
+	// Run - запускает приём сообщений и блокируется до отмены ctx.
+	Run(ctx context.Context) error
+}
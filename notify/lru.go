@@ -0,0 +1,52 @@
+package notify
+
+import "container/list"
+
+// lru - LRU-кэш с фиксированной ёмкостью, ключ - IP клиента.
+// Не потокобезопасен, вызывающий код должен сам заботиться о блокировках.
+type lru struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value *ipState
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (l *lru) get(key string) (*ipState, bool) {
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+func (l *lru) put(key string, value *ipState) {
+	if el, ok := l.items[key]; ok {
+		el.Value.(*lruEntry).value = value
+		l.order.MoveToFront(el)
+		return
+	}
+
+	el := l.order.PushFront(&lruEntry{key: key, value: value})
+	l.items[key] = el
+
+	if l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		if oldest != nil {
+			l.order.Remove(oldest)
+			delete(l.items, oldest.Value.(*lruEntry).key)
+		}
+	}
+}
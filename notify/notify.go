@@ -0,0 +1,145 @@
+// Package notify сидит между NTP-обработчиком и каналом уведомлений:
+// он схлопывает всплески повторных пакетов от одного IP в одно
+// сообщение и подавляет повторные уведомления, пока клиент не
+// пропадал из виду дольше DEDUP_TTL или не сменил гео.
+package notify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultCapacity = 4096
+
+// Sink - получатель уже отфильтрованных уведомлений (например *bot.Bot).
+type Sink interface {
+	Notify(msg string)
+}
+
+// ipState - состояние одного IP в LRU: когда впервые и в последний раз
+// видели клиента в текущей серии, сколько пакетов пришло и какой был
+// последний хеш гео.
+type ipState struct {
+	firstSeen   time.Time
+	lastSeen    time.Time
+	count       int
+	lastGeoHash string
+	flushTimer  *time.Timer
+}
+
+// Notifier - слой дедупликации и схлопывания всплесков.
+type Notifier struct {
+	mu     sync.Mutex
+	states *lru
+	sink   Sink
+	logger *zap.Logger
+
+	window time.Duration // окно схлопывания всплесков от одного IP
+	ttl    time.Duration // DEDUP_TTL: через сколько снова уведомлять про тот же IP
+}
+
+// New - создаёт Notifier поверх sink. window - окно схлопывания
+// повторных пакетов от одного IP в одно "первое обращение / ещё N раз"
+// сообщение, ttl - DEDUP_TTL, через сколько простоя снова уведомлять
+// про тот же IP.
+func New(sink Sink, window, ttl time.Duration, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		states: newLRU(defaultCapacity),
+		sink:   sink,
+		logger: logger,
+		window: window,
+		ttl:    ttl,
+	}
+}
+
+// SetWindow - меняет окно схлопывания всплесков на лету (например, при
+// перечитывании конфигурации по SIGHUP).
+func (n *Notifier) SetWindow(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.window = d
+}
+
+// SetTTL - меняет DEDUP_TTL на лету (команда /setdedup).
+func (n *Notifier) SetTTL(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.ttl = d
+}
+
+// Evaluate - решает судьбу события от ip с заданным geoHash (пустая
+// строка, если гео не определено) и формулировкой msg на случай, если
+// уведомление нужно отправить немедленно. Вызывается на каждый валидный
+// NTP-пакет, до отправки в Telegram.
+func (n *Notifier) Evaluate(ip, geoHash, msg string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+
+	st, ok := n.states.get(ip)
+	if !ok {
+		st = &ipState{firstSeen: now, lastSeen: now, count: 1, lastGeoHash: geoHash}
+		n.states.put(ip, st)
+		n.sink.Notify(msg)
+		n.scheduleFlush(ip, st)
+		return
+	}
+
+	geoChanged := geoHash != "" && st.lastGeoHash != "" && geoHash != st.lastGeoHash
+	expired := now.Sub(st.lastSeen) > n.ttl
+
+	if geoChanged || expired {
+		n.flushLocked(ip, st)
+		st.firstSeen = now
+		st.count = 1
+		st.lastGeoHash = geoHash
+		st.lastSeen = now
+		n.sink.Notify(msg)
+		n.resetFlush(ip, st)
+		return
+	}
+
+	st.count++
+	st.lastSeen = now
+	st.lastGeoHash = geoHash
+	n.resetFlush(ip, st)
+}
+
+func (n *Notifier) scheduleFlush(ip string, st *ipState) {
+	st.flushTimer = time.AfterFunc(n.window, func() { n.flush(ip) })
+}
+
+func (n *Notifier) resetFlush(ip string, st *ipState) {
+	if st.flushTimer != nil {
+		st.flushTimer.Stop()
+	}
+	n.scheduleFlush(ip, st)
+}
+
+func (n *Notifier) flush(ip string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	st, ok := n.states.get(ip)
+	if !ok {
+		return
+	}
+
+	n.flushLocked(ip, st)
+}
+
+// flushLocked - отправляет сводку по накопленному всплеску, если за
+// время окна набралось больше одного пакета. Вызывающий код уже держит n.mu.
+func (n *Notifier) flushLocked(ip string, st *ipState) {
+	if st.count > 1 {
+		n.sink.Notify(fmt.Sprintf(
+			"Клиент %s: %d попаданий подряд с %s по %s (показано одно уведомление)",
+			ip, st.count, st.firstSeen.Format(time.RFC3339), st.lastSeen.Format(time.RFC3339),
+		))
+	}
+	st.count = 0
+}
@@ -0,0 +1,343 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	eventPrefix   = "ev/"
+	ipPrefix      = "ip/"
+	sessionPrefix = "sess/"
+
+	// defaultRetention - окно хранения событий, если retention, переданный
+	// в Open или SetRetention, не задан (<= 0).
+	defaultRetention = 720 * time.Hour
+)
+
+// BadgerStore - Store поверх embedded key-value БД BadgerDB.
+type BadgerStore struct {
+	db     *badger.DB
+	logger *zap.Logger
+	stopGC chan struct{}
+
+	retentionMu sync.RWMutex
+	retention   time.Duration
+}
+
+// Open - открывает (или создаёт) BadgerDB в dir и запускает фоновую
+// компактацию и вытеснение событий старше retention.
+func Open(dir string, retention time.Duration, logger *zap.Logger) (*BadgerStore, error) {
+	opts := badger.DefaultOptions(dir).WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть BadgerDB в %s: %w", dir, err)
+	}
+
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	s := &BadgerStore{
+		db:        db,
+		logger:    logger,
+		retention: retention,
+		stopGC:    make(chan struct{}),
+	}
+
+	go s.runCompaction()
+
+	return s, nil
+}
+
+// Close - останавливает фоновую компактацию и закрывает БД.
+func (s *BadgerStore) Close() error {
+	close(s.stopGC)
+	return s.db.Close()
+}
+
+func eventKey(t time.Time) []byte {
+	key := make([]byte, len(eventPrefix)+8)
+	copy(key, eventPrefix)
+	binary.BigEndian.PutUint64(key[len(eventPrefix):], uint64(t.UnixNano()))
+	return key
+}
+
+func ipKey(ip string, t time.Time) []byte {
+	key := make([]byte, 0, len(ipPrefix)+len(ip)+1+8)
+	key = append(key, ipPrefix...)
+	key = append(key, ip...)
+	key = append(key, '/')
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(t.UnixNano()))
+	return append(key, ts...)
+}
+
+// Append - сохраняет событие под ключом с сортируемой по времени меткой
+// и заводит вторичный индекс по IP.
+func (s *BadgerStore) Append(ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать событие: %w", err)
+	}
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		evKey := eventKey(ev.Timestamp)
+		if err := txn.Set(evKey, data); err != nil {
+			return err
+		}
+		return txn.Set(ipKey(ev.IP, ev.Timestamp), evKey)
+	})
+}
+
+// RecentByIP - последние limit событий для IP, от новых к старым.
+func (s *BadgerStore) RecentByIP(ip string, limit int) ([]Event, error) {
+	var events []Event
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(ipPrefix + ip + "/")
+		// Reverse-итерация начинается с ключа, большего любого в префиксе.
+		seek := append(append([]byte{}, prefix...), 0xFF)
+
+		for it.Seek(seek); it.ValidForPrefix(prefix) && len(events) < limit; it.Next() {
+			var evKey []byte
+			if err := it.Item().Value(func(val []byte) error {
+				evKey = append([]byte{}, val...)
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			item, err := txn.Get(evKey)
+			if err != nil {
+				continue
+			}
+
+			var ev Event
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &ev)
+			}); err != nil {
+				return err
+			}
+
+			events = append(events, ev)
+		}
+
+		return nil
+	})
+
+	return events, err
+}
+
+// epoch - нижняя граница времени, безопасная для eventKey: UnixNano
+// вне примерно 1678-2262 годов переполняет int64, поэтому from до
+// эпохи (в частности, нулевое time.Time) подрезается до неё.
+var epoch = time.Unix(0, 0)
+
+// RangeByTime - все события в полуоткрытом интервале [from, to).
+func (s *BadgerStore) RangeByTime(from, to time.Time) ([]Event, error) {
+	if from.Before(epoch) {
+		from = epoch
+	}
+
+	var events []Event
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		start := eventKey(from)
+		end := eventKey(to)
+
+		for it.Seek(start); it.ValidForPrefix([]byte(eventPrefix)); it.Next() {
+			key := it.Item().Key()
+			if string(key) >= string(end) {
+				break
+			}
+
+			var ev Event
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &ev)
+			}); err != nil {
+				return err
+			}
+
+			events = append(events, ev)
+		}
+
+		return nil
+	})
+
+	return events, err
+}
+
+// TopN - N самых частых значений поля среди событий за последнее окно window.
+func (s *BadgerStore) TopN(field string, window time.Duration, n int) ([]FieldCount, error) {
+	events, err := s.RangeByTime(time.Now().Add(-window), time.Now().Add(time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, ev := range events {
+		var value string
+		switch field {
+		case "country":
+			value = ev.Geo.Country
+		case "asn":
+			value = ev.Geo.ASN
+		default:
+			return nil, fmt.Errorf("неизвестное поле для TopN: %s", field)
+		}
+		if value != "" {
+			counts[value]++
+		}
+	}
+
+	return topFieldCounts(counts, n), nil
+}
+
+// runCompaction - периодически запускает value-log GC Badger и вытесняет
+// события старше s.retention.
+func (s *BadgerStore) runCompaction() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopGC:
+			return
+		case <-ticker.C:
+			s.evictExpired()
+			for s.db.RunValueLogGC(0.5) == nil {
+			}
+		}
+	}
+}
+
+// SetRetention - меняет окно хранения событий на лету (например, при
+// перечитывании конфигурации по SIGHUP). retention <= 0 возвращает
+// значение по умолчанию, как и при Open.
+func (s *BadgerStore) SetRetention(retention time.Duration) {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	s.retentionMu.Lock()
+	s.retention = retention
+	s.retentionMu.Unlock()
+}
+
+func (s *BadgerStore) retentionWindow() time.Duration {
+	s.retentionMu.RLock()
+	defer s.retentionMu.RUnlock()
+	return s.retention
+}
+
+// evictExpired - удаляет события старше s.retention вместе с их
+// записями во вторичном индексе по IP, чтобы retention ограничивал
+// обе структуры, а не только ev/.
+func (s *BadgerStore) evictExpired() {
+	cutoff := eventKey(time.Now().Add(-s.retentionWindow()))
+
+	err := s.db.Update(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(eventPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			key := item.KeyCopy(nil)
+			if string(key) >= string(cutoff) {
+				break
+			}
+
+			var ev Event
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &ev)
+			}); err != nil {
+				return err
+			}
+
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+			if err := txn.Delete(ipKey(ev.IP, ev.Timestamp)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Ошибка вытеснения устаревших событий", zap.Error(err))
+	}
+}
+
+// SaveSession - сохраняет сессионный блоб под именем name (например
+// "mtproto"), чтобы его можно было восстановить после перезапуска.
+func (s *BadgerStore) SaveSession(name string, data []byte) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(sessionPrefix+name), data)
+	})
+}
+
+// LoadSession - читает ранее сохранённый сессионный блоб. Отсутствие
+// сессии - обычная ситуация при первом запуске, поэтому ok=false не
+// является ошибкой.
+func (s *BadgerStore) LoadSession(name string) ([]byte, bool, error) {
+	var data []byte
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(sessionPrefix + name))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			data = append([]byte{}, val...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, data != nil, nil
+}
+
+func topFieldCounts(counts map[string]int, n int) []FieldCount {
+	fields := make([]FieldCount, 0, len(counts))
+	for value, count := range counts {
+		fields = append(fields, FieldCount{Value: value, Count: count})
+	}
+
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j].Count > fields[j-1].Count; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+
+	if len(fields) > n {
+		fields = fields[:n]
+	}
+
+	return fields
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"go.uber.org/zap"
+)
+
+func openTestStore(t *testing.T) *BadgerStore {
+	t.Helper()
+
+	s, err := Open(t.TempDir(), time.Hour, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestBadgerStoreRoundTrip(t *testing.T) {
+	s := openTestStore(t)
+
+	now := time.Now()
+	events := []Event{
+		{Timestamp: now.Add(-2 * time.Minute), IP: "1.1.1.1", Mode: 3, Geo: GeoIP{Country: "US", ASN: "AS1"}},
+		{Timestamp: now.Add(-1 * time.Minute), IP: "1.1.1.1", Mode: 3, Geo: GeoIP{Country: "US", ASN: "AS1"}},
+		{Timestamp: now, IP: "2.2.2.2", Mode: 3, Geo: GeoIP{Country: "DE", ASN: "AS2"}},
+	}
+
+	for _, ev := range events {
+		if err := s.Append(ev); err != nil {
+			t.Fatalf("Append(%+v) error = %v", ev, err)
+		}
+	}
+
+	t.Run("RangeByTime with zero from", func(t *testing.T) {
+		got, err := s.RangeByTime(time.Time{}, now.Add(time.Second))
+		if err != nil {
+			t.Fatalf("RangeByTime() error = %v", err)
+		}
+		if len(got) != len(events) {
+			t.Fatalf("RangeByTime() returned %d events, want %d", len(got), len(events))
+		}
+	})
+
+	t.Run("RangeByTime narrows by window", func(t *testing.T) {
+		got, err := s.RangeByTime(now.Add(-90*time.Second), now.Add(time.Second))
+		if err != nil {
+			t.Fatalf("RangeByTime() error = %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("RangeByTime() returned %d events, want 2", len(got))
+		}
+	})
+
+	t.Run("RecentByIP", func(t *testing.T) {
+		got, err := s.RecentByIP("1.1.1.1", 10)
+		if err != nil {
+			t.Fatalf("RecentByIP() error = %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("RecentByIP() returned %d events, want 2", len(got))
+		}
+		if !got[0].Timestamp.After(got[1].Timestamp) {
+			t.Fatalf("RecentByIP() not sorted newest-first: %+v", got)
+		}
+	})
+
+	t.Run("TopN", func(t *testing.T) {
+		got, err := s.TopN("country", time.Hour, 5)
+		if err != nil {
+			t.Fatalf("TopN() error = %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("TopN() returned %d entries, want 2", len(got))
+		}
+	})
+}
+
+func TestBadgerStoreEvictExpiredPrunesIPIndex(t *testing.T) {
+	s := openTestStore(t)
+
+	stale := Event{Timestamp: time.Now().Add(-2 * time.Hour), IP: "3.3.3.3", Mode: 3}
+	if err := s.Append(stale); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	s.SetRetention(time.Hour)
+	s.evictExpired()
+
+	events, err := s.RecentByIP("3.3.3.3", 10)
+	if err != nil {
+		t.Fatalf("RecentByIP() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("RecentByIP() after eviction returned %d events, want 0 (dangling ip/ index entry)", len(events))
+	}
+
+	// RecentByIP пропускает записи индекса без события, поэтому наличие
+	// самого ключа ip/ проверяется напрямую.
+	err = s.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(ipKey(stale.IP, stale.Timestamp))
+		return err
+	})
+	if err != badger.ErrKeyNotFound {
+		t.Fatalf("ip/ index entry after eviction: Get() error = %v, want ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Package store персистентно хранит события синхронизации NTP и
+// предоставляет выборки по ним для Telegram-бота и будущих HTTP-эндпоинтов.
+package store
+
+import "time"
+
+// Event - одна синхронизация NTP-клиента с геоданными, как она
+// попадает в хранилище из startNTPServer.
+type Event struct {
+	Timestamp time.Time
+	IP        string
+	Mode      uint8
+	Raw       []byte
+	Geo       GeoIP
+}
+
+// GeoIP - геоданные, сопутствующие событию.
+type GeoIP struct {
+	Country string
+	City    string
+	ASN     string
+	ISP     string
+}
+
+// Store - хранилище событий синхронизации.
+type Store interface {
+	// Append - сохраняет новое событие.
+	Append(ev Event) error
+
+	// RecentByIP - последние limit событий для конкретного IP,
+	// от новых к старым.
+	RecentByIP(ip string, limit int) ([]Event, error)
+
+	// RangeByTime - все события в полуоткрытом интервале [from, to).
+	RangeByTime(from, to time.Time) ([]Event, error)
+
+	// TopN - N самых частых значений поля ("country" или "asn")
+	// среди событий за последнее окно window.
+	TopN(field string, window time.Duration, n int) ([]FieldCount, error)
+
+	// Close - освобождает ресурсы хранилища.
+	Close() error
+}
+
+// FieldCount - значение поля и сколько раз оно встретилось в выборке.
+type FieldCount struct {
+	Value string
+	Count int
+}
+
+// SessionStore - хранилище произвольных сессионных блобов (например,
+// auth key, DC и update-seq клиента MTProto), чтобы перезапуск не
+// требовал повторного логина.
+type SessionStore interface {
+	SaveSession(name string, data []byte) error
+	LoadSession(name string) ([]byte, bool, error)
+}